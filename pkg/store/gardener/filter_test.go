@@ -0,0 +1,94 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danielfoehrkn/kubeswitch/types"
+)
+
+func TestFilterIdentifiers(t *testing.T) {
+	identifiers := []string{
+		"dev--shoot--team-a--shoot-1",
+		"dev--shoot--team-b--shoot-2",
+		"dev--seed--shoot-1",
+	}
+	seedIdentifierToProject := map[string]string{
+		"dev--seed--shoot-1": "team-a",
+	}
+
+	t.Run("no ProjectFilter returns identifiers unchanged", func(t *testing.T) {
+		got := filterIdentifiers(identifiers, seedIdentifierToProject, types.LandscapeConfig{})
+		if !reflect.DeepEqual(got, identifiers) {
+			t.Errorf("got %v, want %v", got, identifiers)
+		}
+	})
+
+	t.Run("ProjectFilter keeps matching shoots and the seed backed by a matching shoot", func(t *testing.T) {
+		got := filterIdentifiers(identifiers, seedIdentifierToProject, types.LandscapeConfig{ProjectFilter: "team-a"})
+		want := []string{"dev--shoot--team-a--shoot-1", "dev--seed--shoot-1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ProjectFilter drops a seed whose backing shoot doesn't match", func(t *testing.T) {
+		got := filterIdentifiers(identifiers, seedIdentifierToProject, types.LandscapeConfig{ProjectFilter: "team-b"})
+		want := []string{"dev--shoot--team-b--shoot-2"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("a seed identifier missing from seedIdentifierToProject is dropped, not kept unfiltered", func(t *testing.T) {
+		got := filterIdentifiers(identifiers, nil, types.LandscapeConfig{ProjectFilter: "team-a"})
+		want := []string{"dev--shoot--team-a--shoot-1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestContains(t *testing.T) {
+	if !contains([]string{"a", "b"}, "b") {
+		t.Error("expected contains to find \"b\"")
+	}
+	if contains([]string{"a", "b"}, "c") {
+		t.Error("expected contains not to find \"c\"")
+	}
+	if contains(nil, "a") {
+		t.Error("expected contains on a nil slice to return false")
+	}
+}
+
+func TestNormalizedLandscapes(t *testing.T) {
+	t.Run("falls back to a single synthesized landscape when Landscapes is unset", func(t *testing.T) {
+		s := &Store{ID: "my-store", Config: &types.StoreConfigGardener{GardenerAPIKubeconfigPath: "/path/to/kubeconfig"}}
+		want := []types.LandscapeConfig{{Identity: "my-store", GardenerAPIKubeconfigPath: "/path/to/kubeconfig"}}
+		if got := s.normalizedLandscapes(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns Landscapes unchanged when set", func(t *testing.T) {
+		landscapes := []types.LandscapeConfig{{Identity: "dev"}, {Identity: "live"}}
+		s := &Store{ID: "my-store", Config: &types.StoreConfigGardener{Landscapes: landscapes}}
+		if got := s.normalizedLandscapes(); !reflect.DeepEqual(got, landscapes) {
+			t.Errorf("got %v, want %v", got, landscapes)
+		}
+	})
+}
@@ -0,0 +1,667 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/gardener/gardener/pkg/utils/secrets"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/danielfoehrkn/kubeswitch/types"
+)
+
+// defaultGardenloginPluginName is used when StoreConfigGardener.GardenloginPluginName is unset
+const defaultGardenloginPluginName = "kubectl-gardenlogin"
+
+// caLookupConfigMap and caLookupSecret are the valid entries of StoreConfigGardener.CALookupOrder
+const (
+	caLookupConfigMap = "configmap"
+	caLookupSecret    = "secret"
+)
+
+// defaultCALookupOrder is used when StoreConfigGardener.CALookupOrder is unset
+var defaultCALookupOrder = []string{caLookupConfigMap, caLookupSecret}
+
+// maxConcurrentLandscapeSearches bounds how many landscapes are searched concurrently, so that
+// aggregating hundreds of Gardens does not open an unbounded number of connections at once.
+const maxConcurrentLandscapeSearches = 10
+
+// landscapeClient is a lazily created, cached Garden client (and search index) for one
+// configured landscape. once guards the actual client/index setup so that concurrent callers
+// racing to use the same landscape for the first time block only on each other, not on every
+// other landscape's setup - see Store.getLandscape.
+type landscapeClient struct {
+	config types.LandscapeConfig
+	once   sync.Once
+
+	client client.Client
+	index  *gardenerIndex
+	err    error
+}
+
+// Store is the Gardener kubeconfig store. It lists Shoots from one or more Garden clusters
+// ("landscapes") and resolves their kubeconfigs either from a published kubeconfig Secret or,
+// with the gardenlogin strategy, by assembling a kubeconfig locally that authenticates via an
+// exec credential plugin.
+type Store struct {
+	Logger *logrus.Entry
+	Config *types.StoreConfigGardener
+	ID     string
+
+	mux        sync.Mutex
+	landscapes map[string]*landscapeClient
+}
+
+// NewGardenerStore creates a new Gardener kubeconfig store from the given store configuration.
+// Garden clients are created lazily per landscape on first use, see getLandscapeClient.
+func NewGardenerStore(store types.KubeconfigStore, logger *logrus.Entry) (*Store, error) {
+	config, err := GetStoreConfig(store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		Logger:     logger,
+		Config:     config,
+		ID:         store.ID,
+		landscapes: make(map[string]*landscapeClient),
+	}, nil
+}
+
+func (s *Store) GetID() string {
+	return s.ID
+}
+
+func (s *Store) GetKind() types.StoreKind {
+	return types.StoreKindGardener
+}
+
+// normalizedLandscapes returns the configured landscapes. If StoreConfigGardener.Landscapes is
+// unset, it synthesizes a single landscape from the legacy GardenerAPIKubeconfigPath field so
+// that single-garden stores keep working unchanged.
+func (s *Store) normalizedLandscapes() []types.LandscapeConfig {
+	if len(s.Config.Landscapes) > 0 {
+		return s.Config.Landscapes
+	}
+	return []types.LandscapeConfig{{
+		Identity:                  s.ID,
+		GardenerAPIKubeconfigPath: s.Config.GardenerAPIKubeconfigPath,
+	}}
+}
+
+// getLandscape returns the cached Garden client and search index for the given landscape
+// identity, creating and caching both on first use. In "snapshot" cache mode, the index is
+// hydrated from its on-disk snapshot at this point.
+//
+// s.mux only ever guards the landscapes map itself, not the per-landscape setup below - the
+// actual client/index creation (network and disk I/O) runs under the landscapeClient's own
+// sync.Once, so concurrent first-use calls for different landscapes (as Search fans out) don't
+// serialize behind one another.
+func (s *Store) getLandscape(identity string) (*landscapeClient, error) {
+	s.mux.Lock()
+	lc, ok := s.landscapes[identity]
+	if !ok {
+		var found bool
+		for _, landscape := range s.normalizedLandscapes() {
+			if landscape.Identity == identity {
+				lc = &landscapeClient{config: landscape}
+				s.landscapes[identity] = lc
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.mux.Unlock()
+			return nil, fmt.Errorf("unknown landscape %q", identity)
+		}
+	}
+	s.mux.Unlock()
+
+	lc.once.Do(func() {
+		gardenerConfig := &types.StoreConfigGardener{GardenerAPIKubeconfigPath: lc.config.GardenerAPIKubeconfigPath}
+		restConfig, err := GetGardenRestConfig(gardenerConfig)
+		if err != nil {
+			lc.err = fmt.Errorf("failed to create rest config for landscape %q: %w", identity, err)
+			return
+		}
+
+		scheme := NewGardenerScheme()
+		c, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			lc.err = fmt.Errorf("failed to create garden client for landscape %q: %w", identity, err)
+			return
+		}
+
+		index := newGardenerIndex(identity, s.Config.CacheMode, s.Config.ManagedSeedDiscovery, restConfig, scheme)
+		if s.Config.CacheMode == types.CacheModeSnapshot {
+			if err := index.load(); err != nil {
+				s.Logger.Warnf("failed to load index snapshot for landscape %q: %v", identity, err)
+			}
+		}
+
+		lc.client = c
+		lc.index = index
+	})
+	if lc.err != nil {
+		return nil, lc.err
+	}
+	return lc, nil
+}
+
+// getLandscapeClient returns the cached Garden client for the given landscape identity, creating
+// and caching one on first use.
+func (s *Store) getLandscapeClient(identity string) (client.Client, error) {
+	lc, err := s.getLandscape(identity)
+	if err != nil {
+		return nil, err
+	}
+	return lc.client, nil
+}
+
+// Refresh forces re-hydration of the search index for the given landscapes, regardless of
+// CacheMode (all configured landscapes if landscapeSelector is empty). This package does not
+// wire up a CLI command for it - callers invoke it directly.
+func (s *Store) Refresh(landscapeSelector []string) error {
+	var errs []error
+	for _, landscape := range s.normalizedLandscapes() {
+		if len(landscapeSelector) > 0 && !contains(landscapeSelector, landscape.Identity) {
+			continue
+		}
+
+		lc, err := s.getLandscape(landscape.Identity)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		c := lc.client
+		if s.Config.CacheMode == types.CacheModeInformer {
+			if err := lc.index.ensureStarted(context.Background()); err != nil {
+				errs = append(errs, fmt.Errorf("landscape %q: %w", landscape.Identity, err))
+				continue
+			}
+			c = lc.index.informerCache
+		}
+
+		if err := lc.index.refresh(context.Background(), c); err != nil {
+			errs = append(errs, fmt.Errorf("landscape %q: %w", landscape.Identity, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to refresh %d landscape(s): %v", len(errs), errs)
+	}
+	return nil
+}
+
+// StartSearch lists all Shoot identifiers across the landscapes selected by
+// StoreConfigGardener.LandscapeSelector (all configured landscapes if unset).
+func (s *Store) StartSearch() []SearchResult {
+	return s.Search(s.Config.LandscapeSelector)
+}
+
+// SearchResult is the outcome of searching a single landscape for shoot/seed identifiers
+type SearchResult struct {
+	Landscape   string
+	Identifiers []string
+	// Metadata carries additional per-shoot information (currently: active access
+	// restrictions) keyed by shoot identifier, for the fuzzy search preview to render without
+	// an extra round-trip to the Garden cluster.
+	Metadata map[string]ShootMetadata
+	Error    error
+}
+
+// Search lists all Shoot identifiers across the configured landscapes, optionally restricted to
+// the landscapes named in landscapeSelector (an empty selector searches all of them). Landscapes
+// are searched concurrently, bounded by maxConcurrentLandscapeSearches, and failures are isolated
+// per landscape so one unreachable Garden cluster does not fail the whole search.
+func (s *Store) Search(landscapeSelector []string) []SearchResult {
+	var selected []types.LandscapeConfig
+	for _, landscape := range s.normalizedLandscapes() {
+		if len(landscapeSelector) == 0 || contains(landscapeSelector, landscape.Identity) {
+			selected = append(selected, landscape)
+		}
+	}
+
+	results := make([]SearchResult, len(selected))
+	semaphore := make(chan struct{}, maxConcurrentLandscapeSearches)
+
+	var wg sync.WaitGroup
+	for i, landscape := range selected {
+		wg.Add(1)
+		go func(i int, landscape types.LandscapeConfig) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			identifiers, metadata, err := s.searchLandscape(landscape)
+			if err != nil {
+				s.Logger.Warnf("search failed for landscape %q: %v", landscape.Identity, err)
+			}
+			results[i] = SearchResult{Landscape: landscape.Identity, Identifiers: identifiers, Metadata: metadata, Error: err}
+		}(i, landscape)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// searchLandscape lists the Shoot identifiers for a single landscape, applying its configured
+// ProjectFilter and ShootLabelSelector.
+//
+// With CacheMode "none" (the default) this always does a full LIST of Shoots and Projects.
+// With CacheMode "snapshot" it serves from the on-disk snapshot hydrated in getLandscape once
+// one exists, and only re-lists when Refresh is called explicitly. With CacheMode "informer" it
+// serves from the landscape's long-lived informer cache, starting it on first use.
+func (s *Store) searchLandscape(landscape types.LandscapeConfig) ([]string, map[string]ShootMetadata, error) {
+	lc, err := s.getLandscape(landscape.Identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch s.Config.CacheMode {
+	case types.CacheModeSnapshot:
+		if !lc.index.resourceVersionKnown() {
+			if err := lc.index.refresh(context.Background(), lc.client); err != nil {
+				return nil, nil, fmt.Errorf("failed to hydrate index for landscape %q: %w", landscape.Identity, err)
+			}
+		}
+		identifiers, metadata, seedIdentifierToProject := lc.index.identifiersWithMetadata()
+		return filterIdentifiers(identifiers, seedIdentifierToProject, landscape), metadata, nil
+
+	case types.CacheModeInformer:
+		if err := lc.index.ensureStarted(context.Background()); err != nil {
+			return nil, nil, fmt.Errorf("failed to start informer cache for landscape %q: %w", landscape.Identity, err)
+		}
+		if err := lc.index.refresh(context.Background(), lc.index.informerCache); err != nil {
+			return nil, nil, fmt.Errorf("failed to refresh index for landscape %q: %w", landscape.Identity, err)
+		}
+		identifiers, metadata, seedIdentifierToProject := lc.index.identifiersWithMetadata()
+		return filterIdentifiers(identifiers, seedIdentifierToProject, landscape), metadata, nil
+
+	default:
+		return s.listShootIdentifiers(lc.client, landscape)
+	}
+}
+
+// listShootIdentifiers performs a full LIST of Shoots and Projects against c and returns the
+// resulting Shoot (and, where applicable, Seed) identifiers, applying landscape's ProjectFilter
+// and ShootLabelSelector. A Shoot that is also registered as a Seed - either via the legacy
+// shooted-seed annotation, or via a seedmanagement.gardener.cloud ManagedSeed when
+// ManagedSeedDiscovery is enabled - shows up under both its shoot and seed identifiers.
+func (s *Store) listShootIdentifiers(c client.Client, landscape types.LandscapeConfig) ([]string, map[string]ShootMetadata, error) {
+	var listOpts []client.ListOption
+	if len(landscape.ShootLabelSelector) > 0 {
+		selector, err := labels.Parse(landscape.ShootLabelSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid shootLabelSelector: %w", err)
+		}
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	shootList := &gardencorev1beta1.ShootList{}
+	if err := c.List(context.Background(), shootList, listOpts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list shoots: %w", err)
+	}
+
+	projectList := &gardencorev1beta1.ProjectList{}
+	if err := c.List(context.Background(), projectList); err != nil {
+		return nil, nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	namespaceToProject := BuildNamespaceToProjectMap(projectList)
+
+	var managedSeedShootNames map[string]string
+	if s.Config.ManagedSeedDiscovery {
+		var err error
+		managedSeedShootNames, err = ListManagedSeeds(context.Background(), c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list ManagedSeeds: %w", err)
+		}
+	}
+
+	identifiers := make([]string, 0, len(shootList.Items))
+	metadata := make(map[string]ShootMetadata, len(shootList.Items))
+	for _, shoot := range shootList.Items {
+		project, ok := namespaceToProject[shoot.Namespace]
+		if !ok {
+			continue
+		}
+		if len(landscape.ProjectFilter) > 0 && !strings.HasPrefix(project, landscape.ProjectFilter) {
+			continue
+		}
+
+		shootIdentifier := GetShootIdentifier(landscape.Identity, project, shoot.Name)
+		identifiers = append(identifiers, shootIdentifier)
+		if restrictions := GetAccessRestrictions(shoot); len(restrictions) > 0 {
+			metadata[shootIdentifier] = ShootMetadata{AccessRestrictions: restrictions}
+		}
+
+		if seedName, ok := managedSeedShootNames[shoot.Name]; ok {
+			identifiers = append(identifiers, GetSeedIdentifier(landscape.Identity, seedName))
+		} else if IsShootedSeed(shoot) {
+			identifiers = append(identifiers, GetSeedIdentifier(landscape.Identity, shoot.Name))
+		}
+	}
+	return identifiers, metadata, nil
+}
+
+// filterIdentifiers applies landscape's ProjectFilter to a set of already-built shoot/seed
+// identifiers served from the index. ShootLabelSelector is not applied here, since the index
+// does not currently track shoot labels - it only applies to the "none" cache mode's live list.
+//
+// A seed identifier does not itself encode a project - ParseIdentifier returns an empty project
+// for it - so seedIdentifierToProject supplies the project of the shoot backing each seed
+// identifier instead, keeping seed visibility under ProjectFilter consistent with the "none"
+// cache mode, where a shoot that is also a seed is filtered once, on its own project.
+func filterIdentifiers(identifiers []string, seedIdentifierToProject map[string]string, landscape types.LandscapeConfig) []string {
+	if len(landscape.ProjectFilter) == 0 {
+		return identifiers
+	}
+
+	filtered := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		_, resource, _, _, project, err := ParseIdentifier(identifier)
+		if err != nil {
+			continue
+		}
+		if resource == GardenerResourceSeed {
+			project = seedIdentifierToProject[identifier]
+		}
+		if !strings.HasPrefix(project, landscape.ProjectFilter) {
+			continue
+		}
+		filtered = append(filtered, identifier)
+	}
+	return filtered
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKubeconfigForPath returns the kubeconfig bytes for the given kubeconfig identifier.
+// Depending on the configured KubeconfigStrategy, it either reads the kubeconfig published
+// by Gardener as a Secret, or assembles one locally that authenticates via gardenlogin.
+func (s *Store) GetKubeconfigForPath(path string) ([]byte, error) {
+	landscape, resource, name, namespace, _, err := ParseIdentifier(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identifier %q: %w", path, err)
+	}
+
+	c, err := s.getLandscapeClient(landscape)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource == GardenerResourceSeed {
+		return s.getSeedKubeconfig(c, name)
+	}
+
+	shoot := &gardencorev1beta1.Shoot{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, shoot); err != nil {
+		return nil, fmt.Errorf("failed to get shoot %s/%s: %w", namespace, name, err)
+	}
+
+	if err := s.checkAccessRestrictions(shoot); err != nil {
+		return nil, err
+	}
+
+	switch s.Config.KubeconfigStrategy {
+	case types.KubeconfigStrategyGardenlogin:
+		return s.buildGardenloginKubeconfig(landscape, shoot)
+	case types.KubeconfigStrategySecret, "":
+		return s.getSecretKubeconfig(c, namespace, name)
+	default:
+		return nil, fmt.Errorf("unknown kubeconfig strategy %q", s.Config.KubeconfigStrategy)
+	}
+}
+
+// getSeedKubeconfig resolves the kubeconfig for a Seed identifier. If ManagedSeedDiscovery is
+// enabled and the seed is backed by a seedmanagement.gardener.cloud ManagedSeed, the gardenlet-
+// published seedkubeconfig secret for that ManagedSeed is used. Otherwise it falls back to the
+// legacy behavior of treating the seed as a shooted seed: the kubeconfig of the shoot of the
+// same name in the garden namespace.
+func (s *Store) getSeedKubeconfig(c client.Client, seedName string) ([]byte, error) {
+	if s.Config.ManagedSeedDiscovery {
+		shootNameToSeedName, err := ListManagedSeeds(context.Background(), c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ManagedSeeds: %w", err)
+		}
+
+		for shootName, managedSeedName := range shootNameToSeedName {
+			if managedSeedName == seedName {
+				return s.getManagedSeedKubeconfig(c, seedName, shootName)
+			}
+		}
+	}
+
+	return s.getSecretKubeconfig(c, v1beta1constants.GardenNamespace, seedName)
+}
+
+// getManagedSeedKubeconfig reads the gardenlet-published "<seed-name>.seed-kubeconfig" secret in
+// the garden namespace, which the gardenlet running on shootName writes once it has bootstrapped
+// the seed.
+func (s *Store) getManagedSeedKubeconfig(c client.Client, seedName, shootName string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	secretName := fmt.Sprintf("%s.seed-kubeconfig", seedName)
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: v1beta1constants.GardenNamespace, Name: secretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get gardenlet-managed kubeconfig secret %s/%s for seed %q (backed by shoot %q): %w", v1beta1constants.GardenNamespace, secretName, seedName, shootName, err)
+	}
+
+	kubeconfig, ok := secret.Data[secrets.DataKeyKubeconfig]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s does not contain a kubeconfig", v1beta1constants.GardenNamespace, secretName)
+	}
+	return kubeconfig, nil
+}
+
+// getSecretKubeconfig reads the full kubeconfig published by Gardener as the
+// "<shoot-name>.kubeconfig" Secret in the project namespace.
+func (s *Store) getSecretKubeconfig(c client.Client, namespace, shootName string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	secretName := fmt.Sprintf("%s.kubeconfig", shootName)
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: secretName}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", namespace, secretName, err)
+	}
+
+	kubeconfig, ok := secret.Data[secrets.DataKeyKubeconfig]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s does not contain a kubeconfig", namespace, secretName)
+	}
+	return kubeconfig, nil
+}
+
+// buildGardenloginKubeconfig assembles a kubeconfig for the given Shoot that does not contain
+// any credentials, but instead authenticates via the gardenlogin exec credential plugin, which
+// mints a short-lived client certificate from the shoot's kube-apiserver on demand.
+func (s *Store) buildGardenloginKubeconfig(landscapeIdentity string, shoot *gardencorev1beta1.Shoot) ([]byte, error) {
+	if len(shoot.Status.AdvertisedAddresses) == 0 {
+		return nil, fmt.Errorf("shoot %s/%s does not have any advertised addresses yet", shoot.Namespace, shoot.Name)
+	}
+
+	c, err := s.getLandscapeClient(landscapeIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := s.getShootClusterCA(c, shoot.Namespace, shoot.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine cluster CA for shoot %s/%s: %w", shoot.Namespace, shoot.Name, err)
+	}
+
+	pluginName := s.Config.GardenloginPluginName
+	if len(pluginName) == 0 {
+		pluginName = defaultGardenloginPluginName
+	}
+
+	config := clientcmdapi.NewConfig()
+	userName := fmt.Sprintf("%s--%s", landscapeIdentity, shoot.Name)
+
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{
+		Exec: &clientcmdapi.ExecConfig{
+			APIVersion: "client.authentication.k8s.io/v1",
+			Command:    pluginName,
+			Args: []string{
+				"get-client-certificate",
+				"--garden-cluster-identity", landscapeIdentity,
+				"--shoot-namespace", shoot.Namespace,
+				"--shoot-name", shoot.Name,
+			},
+		},
+	}
+
+	for i, address := range shoot.Status.AdvertisedAddresses {
+		contextName := fmt.Sprintf("%s-%s", shoot.Name, address.Name)
+
+		config.Clusters[contextName] = &clientcmdapi.Cluster{
+			Server:                   address.URL,
+			CertificateAuthorityData: ca,
+		}
+		config.Contexts[contextName] = &clientcmdapi.Context{
+			Cluster:  contextName,
+			AuthInfo: userName,
+		}
+		if i == 0 {
+			config.CurrentContext = contextName
+		}
+	}
+
+	return clientcmdapi.Write(*config)
+}
+
+// getShootClusterCA discovers the CA certificate of a Shoot's cluster, trying the sources
+// configured in StoreConfigGardener.CALookupOrder in order and returning the first hit.
+func (s *Store) getShootClusterCA(c client.Client, namespace, shootName string) ([]byte, error) {
+	lookupOrder := s.Config.CALookupOrder
+	if len(lookupOrder) == 0 {
+		lookupOrder = defaultCALookupOrder
+	}
+
+	caClusterObjectName := fmt.Sprintf("%s.ca-cluster", shootName)
+
+	for _, source := range lookupOrder {
+		switch source {
+		case caLookupConfigMap:
+			cm := &corev1.ConfigMap{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: caClusterObjectName}, cm); err != nil {
+				continue
+			}
+			if ca, ok := cm.Data["ca.crt"]; ok {
+				return []byte(ca), nil
+			}
+		case caLookupSecret:
+			secret := &corev1.Secret{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: caClusterObjectName}, secret); err != nil {
+				continue
+			}
+			if ca, ok := secret.Data["ca.crt"]; ok {
+				return ca, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown CA lookup source %q", source)
+		}
+	}
+
+	return nil, fmt.Errorf("could not find cluster CA in configmap or secret %q in namespace %q", caClusterObjectName, namespace)
+}
+
+// checkAccessRestrictions applies the configured AccessRestrictionMode to the active access
+// restrictions (if any) of the given Shoot, warning, confirming or denying as configured.
+func (s *Store) checkAccessRestrictions(shoot *gardencorev1beta1.Shoot) error {
+	mode := s.Config.AccessRestrictionMode
+	if len(mode) == 0 {
+		mode = types.AccessRestrictionModeIgnore
+	}
+	if mode == types.AccessRestrictionModeIgnore {
+		return nil
+	}
+
+	restrictions := GetAccessRestrictions(*shoot)
+	if len(restrictions) == 0 {
+		return nil
+	}
+
+	if mode == types.AccessRestrictionModeDeny {
+		return fmt.Errorf("refusing to return a kubeconfig for shoot %s/%s: active access restrictions: %s", shoot.Namespace, shoot.Name, strings.Join(restrictions, ", "))
+	}
+
+	for _, name := range restrictions {
+		if message, ok := s.Config.AccessRestrictionMessages[name]; ok {
+			fmt.Fprintf(os.Stderr, "\n%s\n%s\n", message.Title, message.Text)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "\nshoot %s/%s has the active access restriction %q\n", shoot.Namespace, shoot.Name, name)
+	}
+
+	if mode != types.AccessRestrictionModeConfirm {
+		return nil
+	}
+
+	fmt.Fprint(os.Stderr, "\ncontinue anyway? [y/N]: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return fmt.Errorf("aborted due to active access restrictions on shoot %s/%s", shoot.Namespace, shoot.Name)
+	}
+	return nil
+}
+
+// GetSearchPreview returns the text shown in the fuzzy search preview window for the given
+// kubeconfig identifier, including a summary of any active access restrictions.
+func (s *Store) GetSearchPreview(path string) (string, error) {
+	landscape, resource, name, namespace, _, err := ParseIdentifier(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse identifier %q: %w", path, err)
+	}
+	if resource != GardenerResourceShoot {
+		return path, nil
+	}
+
+	c, err := s.getLandscapeClient(landscape)
+	if err != nil {
+		return "", err
+	}
+
+	shoot := &gardencorev1beta1.Shoot{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, shoot); err != nil {
+		return "", fmt.Errorf("failed to get shoot %s/%s: %w", namespace, name, err)
+	}
+
+	restrictions := GetAccessRestrictions(*shoot)
+	if len(restrictions) == 0 {
+		return path, nil
+	}
+	return fmt.Sprintf("%s\n\naccess restrictions: %s", path, strings.Join(restrictions, ", ")), nil
+}
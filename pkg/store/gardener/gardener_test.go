@@ -0,0 +1,111 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+
+	"github.com/danielfoehrkn/kubeswitch/types"
+)
+
+func restrictedShoot() *gardencorev1beta1.Shoot {
+	shoot := &gardencorev1beta1.Shoot{
+		Spec: gardencorev1beta1.ShootSpec{
+			AccessRestrictions: []gardencorev1beta1.AccessRestriction{{Name: "eu-access-only"}},
+		},
+	}
+	shoot.Namespace = "garden-team-a"
+	shoot.Name = "my-shoot"
+	return shoot
+}
+
+func TestCheckAccessRestrictions(t *testing.T) {
+	t.Run("ignore mode never errors, default mode is ignore", func(t *testing.T) {
+		for _, mode := range []types.AccessRestrictionMode{types.AccessRestrictionModeIgnore, ""} {
+			s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: mode}}
+			if err := s.checkAccessRestrictions(restrictedShoot()); err != nil {
+				t.Errorf("mode %q: unexpected error: %v", mode, err)
+			}
+		}
+	})
+
+	t.Run("unrestricted shoot does not error even in deny mode", func(t *testing.T) {
+		s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: types.AccessRestrictionModeDeny}}
+		unrestricted := &gardencorev1beta1.Shoot{}
+		if err := s.checkAccessRestrictions(unrestricted); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("warn mode returns no error", func(t *testing.T) {
+		s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: types.AccessRestrictionModeWarn}}
+		if err := s.checkAccessRestrictions(restrictedShoot()); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("deny mode returns an error naming the restriction", func(t *testing.T) {
+		s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: types.AccessRestrictionModeDeny}}
+		err := s.checkAccessRestrictions(restrictedShoot())
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if !strings.Contains(err.Error(), "eu-access-only") {
+			t.Errorf("expected the error to name the restriction, got %v", err)
+		}
+	})
+
+	t.Run("confirm mode returns nil on 'y'", func(t *testing.T) {
+		s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: types.AccessRestrictionModeConfirm}}
+		withStdin(t, "y\n", func() {
+			if err := s.checkAccessRestrictions(restrictedShoot()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	})
+
+	t.Run("confirm mode returns an error on anything but 'y'", func(t *testing.T) {
+		s := &Store{Config: &types.StoreConfigGardener{AccessRestrictionMode: types.AccessRestrictionModeConfirm}}
+		withStdin(t, "n\n", func() {
+			if err := s.checkAccessRestrictions(restrictedShoot()); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	})
+}
+
+// withStdin temporarily replaces os.Stdin with a pipe that yields input, for the duration of fn.
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatalf("failed to write to pipe: %v", err)
+	}
+	w.Close()
+
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	fn()
+}
@@ -0,0 +1,307 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	seedmanagementv1alpha1 "github.com/gardener/gardener/pkg/apis/seedmanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/danielfoehrkn/kubeswitch/types"
+)
+
+// switchStateDir is where per-landscape snapshots are persisted, mirroring the directory
+// kubeswitch already uses for other local state under the user's kube directory.
+const switchStateDir = ".kube/.switch_state"
+
+// gardenerIndexSnapshot is the data persisted to / hydrated from disk between kubeswitch
+// invocations when CacheMode is "snapshot".
+type gardenerIndexSnapshot struct {
+	ResourceVersion          string
+	NamespaceToProject       map[string]string
+	ShootUIDToIdentifier     map[string]string
+	ShootUIDToSeedIdentifier map[string]string
+	ShootUIDToMetadata       map[string]ShootMetadata
+	SeedIdentifierToProject  map[string]string
+}
+
+// gardenerIndex maintains an in-memory view of a landscape's Shoots and Projects, avoiding a
+// full re-list of both on every search. In "snapshot" mode it is hydrated once from a snapshot
+// file persisted under $HOME/.kube/.switch_state and only updated again within this process by
+// an explicit Store.Refresh.
+// In "informer" mode it additionally starts controller-runtime informers that keep it current
+// for the lifetime of the process.
+type gardenerIndex struct {
+	landscapeIdentity    string
+	mode                 types.CacheMode
+	managedSeedDiscovery bool
+	restConfig           *rest.Config
+	scheme               *runtime.Scheme
+
+	mux                      sync.RWMutex
+	resourceVersion          string
+	namespaceToProject       map[string]string
+	shootUIDToIdentifier     map[string]string
+	shootUIDToSeedIdentifier map[string]string
+	shootUIDToMetadata       map[string]ShootMetadata
+	// seedIdentifierToProject tracks the project of the shoot backing each seed identifier, since
+	// a seed identifier does not itself encode a project - see filterIdentifiers.
+	seedIdentifierToProject map[string]string
+
+	informerCache cache.Cache
+	started       bool
+}
+
+func newGardenerIndex(landscapeIdentity string, mode types.CacheMode, managedSeedDiscovery bool, restConfig *rest.Config, scheme *runtime.Scheme) *gardenerIndex {
+	return &gardenerIndex{
+		landscapeIdentity:        landscapeIdentity,
+		mode:                     mode,
+		managedSeedDiscovery:     managedSeedDiscovery,
+		restConfig:               restConfig,
+		scheme:                   scheme,
+		namespaceToProject:       make(map[string]string),
+		shootUIDToIdentifier:     make(map[string]string),
+		shootUIDToSeedIdentifier: make(map[string]string),
+		shootUIDToMetadata:       make(map[string]ShootMetadata),
+		seedIdentifierToProject:  make(map[string]string),
+	}
+}
+
+// snapshotPath returns the path of the on-disk snapshot for this landscape
+func snapshotPath(landscapeIdentity string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, switchStateDir, fmt.Sprintf("gardener-%s.gob", landscapeIdentity)), nil
+}
+
+// load hydrates the index from its on-disk snapshot, if one exists. It is not an error for no
+// snapshot to exist yet - the index just starts out empty and Refresh populates it.
+func (i *gardenerIndex) load() error {
+	path, err := snapshotPath(i.landscapeIdentity)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot %q: %w", path, err)
+	}
+	defer file.Close()
+
+	var snapshot gardenerIndexSnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot %q: %w", path, err)
+	}
+
+	i.mux.Lock()
+	defer i.mux.Unlock()
+	i.resourceVersion = snapshot.ResourceVersion
+	i.namespaceToProject = snapshot.NamespaceToProject
+	i.shootUIDToIdentifier = snapshot.ShootUIDToIdentifier
+	i.shootUIDToSeedIdentifier = snapshot.ShootUIDToSeedIdentifier
+	i.shootUIDToMetadata = snapshot.ShootUIDToMetadata
+	i.seedIdentifierToProject = snapshot.SeedIdentifierToProject
+	return nil
+}
+
+// persist writes the current state of the index to its on-disk snapshot
+func (i *gardenerIndex) persist() error {
+	path, err := snapshotPath(i.landscapeIdentity)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create switch state directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot %q for writing: %w", path, err)
+	}
+	defer file.Close()
+
+	i.mux.RLock()
+	snapshot := gardenerIndexSnapshot{
+		ResourceVersion:          i.resourceVersion,
+		NamespaceToProject:       i.namespaceToProject,
+		ShootUIDToIdentifier:     i.shootUIDToIdentifier,
+		ShootUIDToSeedIdentifier: i.shootUIDToSeedIdentifier,
+		ShootUIDToMetadata:       i.shootUIDToMetadata,
+		SeedIdentifierToProject:  i.seedIdentifierToProject,
+	}
+	i.mux.RUnlock()
+
+	if err := gob.NewEncoder(file).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to encode snapshot %q: %w", path, err)
+	}
+	return nil
+}
+
+// ensureStarted lazily starts the controller-runtime informers backing the index when running
+// in "informer" mode. It is a no-op for any other mode, and a no-op if already started.
+func (i *gardenerIndex) ensureStarted(ctx context.Context) error {
+	if i.mode != types.CacheModeInformer {
+		return nil
+	}
+
+	i.mux.Lock()
+	defer i.mux.Unlock()
+	if i.started {
+		return nil
+	}
+
+	informerCache, err := cache.New(i.restConfig, cache.Options{Scheme: i.scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create informer cache: %w", err)
+	}
+
+	for _, obj := range []client.Object{
+		&gardencorev1beta1.Shoot{},
+		&gardencorev1beta1.Project{},
+		&gardencorev1beta1.Seed{},
+		&seedmanagementv1alpha1.ManagedSeed{},
+		&corev1.Secret{},
+	} {
+		if _, err := informerCache.GetInformer(ctx, obj); err != nil {
+			return fmt.Errorf("failed to start informer for %T: %w", obj, err)
+		}
+	}
+
+	go func() {
+		if err := informerCache.Start(ctx); err != nil {
+			utilruntime.HandleError(fmt.Errorf("informer cache for landscape %q stopped: %w", i.landscapeIdentity, err))
+		}
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		return fmt.Errorf("informer cache for landscape %q failed to sync", i.landscapeIdentity)
+	}
+
+	i.informerCache = informerCache
+	i.started = true
+	return nil
+}
+
+// refresh rebuilds namespaceToProject, shootUIDToIdentifier and shootUIDToSeedIdentifier from
+// the given client (either a direct Garden client in "snapshot"/"none" mode, or the informer
+// cache's client in "informer" mode) and, in "snapshot" mode, persists the result to disk.
+func (i *gardenerIndex) refresh(ctx context.Context, c client.Client) error {
+	projectList := &gardencorev1beta1.ProjectList{}
+	if err := c.List(ctx, projectList); err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	namespaceToProject := BuildNamespaceToProjectMap(projectList)
+
+	shootList := &gardencorev1beta1.ShootList{}
+	if err := c.List(ctx, shootList); err != nil {
+		return fmt.Errorf("failed to list shoots: %w", err)
+	}
+
+	var managedSeedShootNames map[string]string
+	if i.managedSeedDiscovery {
+		var err error
+		managedSeedShootNames, err = ListManagedSeeds(ctx, c)
+		if err != nil {
+			return fmt.Errorf("failed to list ManagedSeeds: %w", err)
+		}
+	}
+
+	shootUIDToIdentifier := make(map[string]string, len(shootList.Items))
+	shootUIDToSeedIdentifier := make(map[string]string)
+	shootUIDToMetadata := make(map[string]ShootMetadata)
+	seedIdentifierToProject := make(map[string]string)
+	for _, shoot := range shootList.Items {
+		project, ok := namespaceToProject[shoot.Namespace]
+		if !ok {
+			continue
+		}
+		shootUIDToIdentifier[string(shoot.UID)] = GetShootIdentifier(i.landscapeIdentity, project, shoot.Name)
+
+		if restrictions := GetAccessRestrictions(shoot); len(restrictions) > 0 {
+			shootUIDToMetadata[string(shoot.UID)] = ShootMetadata{AccessRestrictions: restrictions}
+		}
+
+		var seedIdentifier string
+		if seedName, ok := managedSeedShootNames[shoot.Name]; ok {
+			seedIdentifier = GetSeedIdentifier(i.landscapeIdentity, seedName)
+		} else if IsShootedSeed(shoot) {
+			seedIdentifier = GetSeedIdentifier(i.landscapeIdentity, shoot.Name)
+		}
+		if seedIdentifier != "" {
+			shootUIDToSeedIdentifier[string(shoot.UID)] = seedIdentifier
+			seedIdentifierToProject[seedIdentifier] = project
+		}
+	}
+
+	i.mux.Lock()
+	i.namespaceToProject = namespaceToProject
+	i.shootUIDToIdentifier = shootUIDToIdentifier
+	i.shootUIDToSeedIdentifier = shootUIDToSeedIdentifier
+	i.shootUIDToMetadata = shootUIDToMetadata
+	i.seedIdentifierToProject = seedIdentifierToProject
+	i.resourceVersion = shootList.ResourceVersion
+	i.mux.Unlock()
+
+	if i.mode == types.CacheModeSnapshot {
+		return i.persist()
+	}
+	return nil
+}
+
+// resourceVersionKnown reports whether the index has been hydrated, either from a snapshot or
+// from a prior refresh.
+func (i *gardenerIndex) resourceVersionKnown() bool {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+	return i.resourceVersion != ""
+}
+
+// identifiersWithMetadata returns the currently known shoot and shooted/managed-seed identifiers,
+// a map from shoot identifier to ShootMetadata for every shoot that has at least one active
+// access restriction, and a map from seed identifier to the project of the shoot backing it (a
+// seed identifier does not itself encode a project - see filterIdentifiers).
+func (i *gardenerIndex) identifiersWithMetadata() ([]string, map[string]ShootMetadata, map[string]string) {
+	i.mux.RLock()
+	defer i.mux.RUnlock()
+
+	result := make([]string, 0, len(i.shootUIDToIdentifier)+len(i.shootUIDToSeedIdentifier))
+	metadata := make(map[string]ShootMetadata, len(i.shootUIDToMetadata))
+	for uid, identifier := range i.shootUIDToIdentifier {
+		result = append(result, identifier)
+		if m, ok := i.shootUIDToMetadata[uid]; ok {
+			metadata[identifier] = m
+		}
+	}
+	for _, identifier := range i.shootUIDToSeedIdentifier {
+		result = append(result, identifier)
+	}
+	return result, metadata, i.seedIdentifierToProject
+}
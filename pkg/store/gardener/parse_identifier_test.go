@@ -0,0 +1,89 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import "testing"
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		wantLandscape string
+		wantResource  GardenerResource
+		wantName      string
+		wantNamespace string
+		wantProject   string
+		wantErr       bool
+	}{
+		{
+			name:          "shoot identifier",
+			path:          "dev--shoot--team-a--my-shoot",
+			wantLandscape: "dev",
+			wantResource:  GardenerResourceShoot,
+			wantName:      "my-shoot",
+			wantNamespace: "garden-team-a",
+			wantProject:   "team-a",
+		},
+		{
+			name:          "seed identifier",
+			path:          "dev--seed--my-seed",
+			wantLandscape: "dev",
+			wantResource:  GardenerResourceSeed,
+			wantName:      "my-seed",
+			wantNamespace: "",
+			wantProject:   "",
+		},
+		{
+			name:    "four segments without 'shoot' is rejected",
+			path:    "dev--foo--team-a--my-shoot",
+			wantErr: true,
+		},
+		{
+			name:    "three segments without 'seed' is rejected",
+			path:    "dev--foo--my-seed",
+			wantErr: true,
+		},
+		{
+			name:    "wrong number of segments is rejected",
+			path:    "dev--my-shoot",
+			wantErr: true,
+		},
+		{
+			name:    "empty string is rejected",
+			path:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			landscape, resource, name, namespace, project, err := ParseIdentifier(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if landscape != tt.wantLandscape || resource != tt.wantResource || name != tt.wantName || namespace != tt.wantNamespace || project != tt.wantProject {
+				t.Errorf("ParseIdentifier(%q) = (%q, %q, %q, %q, %q), want (%q, %q, %q, %q, %q)",
+					tt.path, landscape, resource, name, namespace, project,
+					tt.wantLandscape, tt.wantResource, tt.wantName, tt.wantNamespace, tt.wantProject)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package gardener
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -60,12 +62,19 @@ func GetStoreConfig(store types.KubeconfigStore) (*types.StoreConfigGardener, er
 	return storeConfig, nil
 }
 
-func GetGardenClient(config *types.StoreConfigGardener) (client.Client, error) {
+// NewGardenerScheme returns the runtime.Scheme understood by Garden clients: core/v1 plus the
+// Gardener core and seedmanagement APIs.
+func NewGardenerScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(gardencorev1beta1.AddToScheme(scheme))
 	utilruntime.Must(seedmanagementv1alpha1.AddToScheme(scheme))
+	return scheme
+}
 
+// GetGardenRestConfig builds the REST config for the Garden cluster pointed to by
+// config.GardenerAPIKubeconfigPath
+func GetGardenRestConfig(config *types.StoreConfigGardener) (*rest.Config, error) {
 	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: config.GardenerAPIKubeconfigPath},
 		&clientcmd.ConfigOverrides{})
@@ -74,9 +83,17 @@ func GetGardenClient(config *types.StoreConfigGardener) (client.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf(fmt.Sprintf("unable to create rest config: %v", err))
 	}
+	return restConfig, nil
+}
+
+func GetGardenClient(config *types.StoreConfigGardener) (client.Client, error) {
+	restConfig, err := GetGardenRestConfig(config)
+	if err != nil {
+		return nil, err
+	}
 
 	k8sclient, err := client.New(restConfig, client.Options{
-		Scheme: scheme,
+		Scheme: NewGardenerScheme(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf(fmt.Sprintf("unable to create garden client: %v", err))
@@ -112,6 +129,10 @@ func GetShootIdentifier(landscape, project, shoot string) string {
 // 2) name of the resource
 // 3) optionally the namespace
 // 3) optionally the project name
+//
+// A seed identifier does not by itself say whether it is backed by a ManagedSeed or is a
+// legacy annotation-based shooted seed - callers resolving its kubeconfig disambiguate that
+// at runtime via ListManagedSeeds, see Store.getSeedKubeconfig.
 func ParseIdentifier(path string) (string, GardenerResource, string, string, string, error) {
 	split := strings.Split(path, "--")
 	switch len(split) {
@@ -131,6 +152,26 @@ func ParseIdentifier(path string) (string, GardenerResource, string, string, str
 	}
 }
 
+// ListManagedSeeds lists all ManagedSeed resources on the Garden cluster and returns a map from
+// the name of the backing Shoot to the name of the ManagedSeed, which is also the Seed's name.
+// This covers seeds registered the modern way, as opposed to the legacy annotation-based
+// shooted seed detected by IsShootedSeed.
+func ListManagedSeeds(ctx context.Context, c client.Client) (map[string]string, error) {
+	managedSeedList := &seedmanagementv1alpha1.ManagedSeedList{}
+	if err := c.List(ctx, managedSeedList); err != nil {
+		return nil, fmt.Errorf("failed to list ManagedSeeds: %w", err)
+	}
+
+	shootNameToSeedName := make(map[string]string, len(managedSeedList.Items))
+	for _, managedSeed := range managedSeedList.Items {
+		if managedSeed.Spec.Shoot == nil {
+			continue
+		}
+		shootNameToSeedName[managedSeed.Spec.Shoot.Name] = managedSeed.Name
+	}
+	return shootNameToSeedName, nil
+}
+
 func GetSecretNamespaceNameToSecret(log *logrus.Entry, secretList *corev1.SecretList) map[string]corev1.Secret {
 	shootNameToSecret := make(map[string]corev1.Secret, len(secretList.Items))
 	for _, secret := range secretList.Items {
@@ -175,3 +216,33 @@ func IsShootedSeed(shoot gardencorev1beta1.Shoot) bool {
 	}
 	return false
 }
+
+// AnnotationEUAccessForClusterAddons is the legacy annotation used by Gardener to mark a Shoot's
+// cluster addons as restricted to the EU, before this was expressed in Spec.AccessRestrictions.
+const AnnotationEUAccessForClusterAddons = "support.gardener.cloud/eu-access-for-cluster-addons"
+
+// ShootMetadata carries additional Shoot information surfaced by the search index and preview
+// that is not already encoded in the kubeconfig identifier.
+type ShootMetadata struct {
+	// AccessRestrictions are the names of all access restrictions currently active on the Shoot
+	// itself. Restrictions expressed only on the Shoot's Seed are not considered - see
+	// GetAccessRestrictions.
+	AccessRestrictions []string
+}
+
+// GetAccessRestrictions collects the names of all access restrictions active on the given Shoot,
+// combining the modern Spec.AccessRestrictions field with the legacy annotation-based restriction.
+// This is Shoot-only: restrictions expressed on the Shoot's Seed rather than the Shoot itself
+// (also a valid Gardener pattern) are not merged in.
+func GetAccessRestrictions(shoot gardencorev1beta1.Shoot) []string {
+	var restrictions []string
+	for _, restriction := range shoot.Spec.AccessRestrictions {
+		restrictions = append(restrictions, restriction.Name)
+	}
+
+	if shoot.Annotations[AnnotationEUAccessForClusterAddons] == "true" {
+		restrictions = append(restrictions, "eu-access-only")
+	}
+
+	return restrictions
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gardener
+
+import (
+	"reflect"
+	"testing"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+)
+
+func TestGetAccessRestrictions(t *testing.T) {
+	tests := []struct {
+		name  string
+		shoot gardencorev1beta1.Shoot
+		want  []string
+	}{
+		{
+			name:  "no restrictions",
+			shoot: gardencorev1beta1.Shoot{},
+			want:  nil,
+		},
+		{
+			name: "spec restriction only",
+			shoot: gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					AccessRestrictions: []gardencorev1beta1.AccessRestriction{
+						{Name: "eu-access-only"},
+					},
+				},
+			},
+			want: []string{"eu-access-only"},
+		},
+		{
+			name:  "legacy annotation only",
+			shoot: shootWithAnnotation(AnnotationEUAccessForClusterAddons, "true"),
+			want:  []string{"eu-access-only"},
+		},
+		{
+			name:  "legacy annotation set to false is ignored",
+			shoot: shootWithAnnotation(AnnotationEUAccessForClusterAddons, "false"),
+			want:  nil,
+		},
+		{
+			name: "both spec and legacy annotation are combined",
+			shoot: func() gardencorev1beta1.Shoot {
+				s := shootWithAnnotation(AnnotationEUAccessForClusterAddons, "true")
+				s.Spec.AccessRestrictions = []gardencorev1beta1.AccessRestriction{{Name: "confidential"}}
+				return s
+			}(),
+			want: []string{"confidential", "eu-access-only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetAccessRestrictions(tt.shoot)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAccessRestrictions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func shootWithAnnotation(key, value string) gardencorev1beta1.Shoot {
+	shoot := gardencorev1beta1.Shoot{}
+	shoot.Annotations = map[string]string{key: value}
+	return shoot
+}
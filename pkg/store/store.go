@@ -0,0 +1,27 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "github.com/danielfoehrkn/kubeswitch/types"
+
+// KubeconfigStore is implemented by every kubeconfig store kubeswitch can search
+type KubeconfigStore interface {
+	// GetID returns the (unique) identifier of this store
+	GetID() string
+	// GetKind returns the kind of this store
+	GetKind() types.StoreKind
+	// GetKubeconfigForPath returns the kubeconfig bytes for the given identifier
+	GetKubeconfigForPath(path string) ([]byte, error)
+}
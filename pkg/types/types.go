@@ -0,0 +1,163 @@
+// Copyright 2021 Daniel Foehr
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// StoreKind is the kind of a configured kubeconfig store
+type StoreKind string
+
+const (
+	// StoreKindGardener is the Gardener kubeconfig store
+	StoreKindGardener StoreKind = "gardener"
+)
+
+// KubeconfigStore is a configured store that kubeswitch searches for kubeconfigs
+type KubeconfigStore struct {
+	// Kind is the kind of the kubeconfig store
+	Kind StoreKind `yaml:"kind"`
+	// ID is an optional unique identifier of this store, required if several stores
+	// of the same kind are configured
+	ID string `yaml:"id,omitempty"`
+	// Config is the store-kind specific configuration
+	Config interface{} `yaml:"config"`
+}
+
+// KubeconfigStrategy determines how the Gardener store obtains the kubeconfig for a Shoot
+type KubeconfigStrategy string
+
+const (
+	// KubeconfigStrategySecret reads the full kubeconfig published to the project namespace
+	// as a `<shoot-name>.kubeconfig` Secret. This is the legacy, default behavior.
+	KubeconfigStrategySecret KubeconfigStrategy = "secret"
+	// KubeconfigStrategyGardenlogin assembles a kubeconfig locally from Garden cluster data,
+	// authenticating via the kubectl-gardenlogin exec credential plugin instead of reading
+	// a full kubeconfig from a Secret.
+	KubeconfigStrategyGardenlogin KubeconfigStrategy = "gardenlogin"
+)
+
+// LandscapeConfig configures one Garden cluster ("landscape") that a Gardener store fans its
+// search out to. Several landscapes can be aggregated under a single KubeconfigStore entry.
+type LandscapeConfig struct {
+	// Identity uniquely identifies this landscape, e.g. "dev", "canary", "live-eu". It is used
+	// as the landscape prefix of every identifier produced for shoots/seeds in this landscape.
+	Identity string `yaml:"identity"`
+	// GardenerAPIKubeconfigPath is the path on the local filesystem to the kubeconfig of this
+	// landscape's Garden cluster
+	GardenerAPIKubeconfigPath string `yaml:"gardenerAPIKubeconfigPath"`
+	// Paths is accepted in the configuration but not read by the Gardener store: the whole
+	// landscape is searched whatever it is set to.
+	Paths []string `yaml:"paths,omitempty"`
+	// ProjectFilter optionally restricts the search to projects matching this name/prefix
+	ProjectFilter string `yaml:"projectFilter,omitempty"`
+	// ShootLabelSelector optionally restricts the search to shoots matching this label selector.
+	// It is applied only with CacheMode "none"; searches served from the "snapshot" or
+	// "informer" index do not filter by labels.
+	ShootLabelSelector string `yaml:"shootLabelSelector,omitempty"`
+}
+
+// StoreConfigGardener is the configuration for the Gardener kubeconfig store
+type StoreConfigGardener struct {
+	// GardenerAPIKubeconfigPath is the path on the local filesystem to the kubeconfig
+	// of the Garden cluster this store targets. Ignored if Landscapes is set.
+	GardenerAPIKubeconfigPath string `yaml:"gardenerAPIKubeconfigPath"`
+
+	// Landscapes allows aggregating several Garden clusters ("landscapes") under a single
+	// Gardener store entry, e.g. one per environment or region. Search fans out across all
+	// configured landscapes concurrently. If set, GardenerAPIKubeconfigPath is ignored.
+	Landscapes []LandscapeConfig `yaml:"landscapes,omitempty"`
+
+	// KubeconfigStrategy selects how shoot kubeconfigs are obtained.
+	// Defaults to "secret" for backwards compatibility.
+	KubeconfigStrategy KubeconfigStrategy `yaml:"kubeconfigStrategy,omitempty"`
+
+	// GardenloginPluginName is the name of the credential exec plugin invoked to mint a
+	// client certificate when KubeconfigStrategy is "gardenlogin".
+	// Defaults to "kubectl-gardenlogin".
+	GardenloginPluginName string `yaml:"gardenloginPluginName,omitempty"`
+
+	// CALookupOrder configures the order in which the cluster CA is discovered when
+	// KubeconfigStrategy is "gardenlogin". Valid entries are "configmap" and "secret".
+	// Defaults to ["configmap", "secret"].
+	CALookupOrder []string `yaml:"caLookupOrder,omitempty"`
+
+	// AccessRestrictionMode controls what happens when a Shoot carries one or more access
+	// restrictions (e.g. "eu-access-only"). Defaults to "ignore" for backwards compatibility.
+	AccessRestrictionMode AccessRestrictionMode `yaml:"accessRestrictionMode,omitempty"`
+
+	// AccessRestrictionMessages maps an access restriction name to the message displayed to
+	// the user in "warn"/"confirm" mode.
+	AccessRestrictionMessages map[string]AccessRestrictionMessage `yaml:"accessRestrictionMessages,omitempty"`
+
+	// ManagedSeedDiscovery enables resolving Seed identifiers via the seedmanagement.gardener.cloud
+	// ManagedSeed resource in addition to the legacy annotation-based shooted seed. Defaults to
+	// false so users on older Gardener versions keep the annotation-only behavior.
+	ManagedSeedDiscovery bool `yaml:"managedSeedDiscovery,omitempty"`
+
+	// LandscapeSelector restricts search to the named landscapes out of Landscapes; an empty
+	// selector searches all configured landscapes. If Landscapes is unset, the single landscape
+	// built from GardenerAPIKubeconfigPath is named after the store's ID, and a non-empty
+	// selector that does not contain that ID excludes it. This is
+	// set programmatically by the caller of Store.Search/StartSearch - wiring it up to a CLI
+	// flag is outside the scope of this package.
+	LandscapeSelector []string `yaml:"-"`
+
+	// CacheMode controls how the Gardener store avoids a full re-list of Shoots/Projects on
+	// every search. Defaults to "none" (always list).
+	CacheMode CacheMode `yaml:"cacheMode,omitempty"`
+}
+
+// CacheMode determines how the Gardener store caches Shoot/Project/Seed state between searches
+type CacheMode string
+
+const (
+	// CacheModeNone performs a full LIST against the Garden cluster on every search. Default.
+	CacheModeNone CacheMode = "none"
+	// CacheModeSnapshot hydrates the index from a persisted on-disk snapshot, falling back to a
+	// full LIST (and writing a fresh snapshot) only if none exists yet or the in-process index
+	// was never hydrated. It is not re-listed on subsequent searches - Store.Refresh is the only
+	// way to invalidate it, and nothing in this package calls Refresh on its own, so a snapshot
+	// goes stale (missing new/deleted shoots) until something external calls Refresh or the
+	// snapshot file is removed. Well suited to short-lived, cron-style kubeswitch invocations
+	// that call Refresh once up front.
+	CacheModeSnapshot CacheMode = "snapshot"
+	// CacheModeInformer starts and maintains long-lived controller-runtime informers for the
+	// lifetime of the process, serving searches from memory. Well suited to long-lived shells
+	// or servers.
+	CacheModeInformer CacheMode = "informer"
+)
+
+// AccessRestrictionMode determines how the Gardener store reacts to a Shoot's access restrictions
+type AccessRestrictionMode string
+
+const (
+	// AccessRestrictionModeIgnore does not consider access restrictions at all. This is the default.
+	AccessRestrictionModeIgnore AccessRestrictionMode = "ignore"
+	// AccessRestrictionModeWarn prints a warning for any active access restriction, but still
+	// returns the kubeconfig.
+	AccessRestrictionModeWarn AccessRestrictionMode = "warn"
+	// AccessRestrictionModeConfirm prints a warning for any active access restriction and blocks
+	// on an interactive y/N confirmation before returning the kubeconfig.
+	AccessRestrictionModeConfirm AccessRestrictionMode = "confirm"
+	// AccessRestrictionModeDeny refuses to return a kubeconfig for a Shoot with any active
+	// access restriction.
+	AccessRestrictionModeDeny AccessRestrictionMode = "deny"
+)
+
+// AccessRestrictionMessage is the user-facing message shown for a given access restriction
+type AccessRestrictionMessage struct {
+	// Title is a short, one-line heading for the restriction
+	Title string `yaml:"title"`
+	// Text is the full explanation shown below the title
+	Text string `yaml:"text"`
+}